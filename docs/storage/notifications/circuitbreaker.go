@@ -0,0 +1,257 @@
+package notifications
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// circuitState is the state of a circuitBreakerSink's breaker.
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// CircuitOpenError is returned by circuitBreakerSink.Write while the
+// breaker is open, or half-open with a probe already in flight. Events
+// passed to a Write that returns CircuitOpenError are forwarded to the
+// sink's DeadLetterSink when one is configured, and dropped otherwise.
+type CircuitOpenError struct {
+	Endpoint string
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("notifications: circuit breaker open for endpoint %q", e.Endpoint)
+}
+
+// CircuitBreakerPolicy configures when a circuitBreakerSink trips open
+// and how it recovers.
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the fraction of failed requests, in [0,1],
+	// within the current window that trips the breaker open.
+	FailureThreshold float64
+
+	// MinRequests is the minimum number of requests that must be
+	// observed in the closed state before FailureThreshold is
+	// evaluated, avoiding trips on a handful of unlucky early requests.
+	MinRequests int
+
+	// Cooldown is how long the breaker stays open before allowing a
+	// single half-open probe request through to the endpoint.
+	Cooldown time.Duration
+
+	// ReplayBudget caps how many dead-lettered events are replayed, in
+	// FIFO order, after a probe succeeds and the breaker closes.
+	ReplayBudget int
+}
+
+// circuitBreakerListener is notified of breaker state transitions and
+// dead-letter queue activity, so they can be surfaced through metrics.
+type circuitBreakerListener interface {
+	opened()
+	closed()
+	deadLettered(n int)
+	replayed(n int)
+}
+
+// circuitBreakerSink wraps a Sink with a closed/open/half-open breaker.
+// While open, Write fails fast with a CircuitOpenError and diverts events
+// to a DeadLetterSink, rather than blocking goroutines or adding load to
+// an endpoint that is already down.
+type circuitBreakerSink struct {
+	endpoint string
+	sink     Sink
+	dlq      DeadLetterSink
+	policy   CircuitBreakerPolicy
+	listener circuitBreakerListener
+	clock    clock
+
+	mu       sync.Mutex
+	state    circuitState
+	openedAt time.Time
+	probing  bool
+	total    int
+	failures int
+
+	// dlqMu serializes all reads and appends against dlq, independent of
+	// mu. replay releases dlqMu for the (potentially slow) call to
+	// cb.sink.Write, re-acquiring it only to re-enqueue on failure, so a
+	// concurrent deadLetter still fails fast instead of blocking on the
+	// whole redelivery; see replay for the FIFO trade-off this implies.
+	dlqMu sync.Mutex
+}
+
+// newCircuitBreakerSink wraps sink with a circuit breaker governed by
+// policy, dead-lettering events to dlq while the breaker is open. dlq may
+// be nil, in which case events are dropped rather than buffered.
+func newCircuitBreakerSink(endpoint string, sink Sink, dlq DeadLetterSink, policy CircuitBreakerPolicy, listener circuitBreakerListener) *circuitBreakerSink {
+	return &circuitBreakerSink{
+		endpoint: endpoint,
+		sink:     sink,
+		dlq:      dlq,
+		policy:   policy,
+		listener: listener,
+		clock:    realClock{},
+		state:    circuitClosed,
+	}
+}
+
+// Write delivers events through the underlying sink while the breaker is
+// closed or probing, and dead-letters them immediately while it is open.
+func (cb *circuitBreakerSink) Write(events ...Event) error {
+	if cb.admit() {
+		err := cb.sink.Write(events...)
+		if cb.report(err) {
+			cb.replay()
+		}
+		return err
+	}
+
+	return cb.deadLetter(events)
+}
+
+// admit reports whether the call should be allowed through to the
+// underlying sink, transitioning open->half-open once the cooldown has
+// elapsed and claiming the single half-open probe slot if so.
+func (cb *circuitBreakerSink) admit() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == circuitOpen && cb.clock.Now().Sub(cb.openedAt) >= cb.policy.Cooldown {
+		cb.state = circuitHalfOpen
+		cb.probing = false
+	}
+
+	switch cb.state {
+	case circuitClosed:
+		return true
+	case circuitHalfOpen:
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	default: // circuitOpen
+		return false
+	}
+}
+
+// report records the outcome of a call that was admitted, driving the
+// closed->open and half-open->{closed,open} transitions. It reports
+// whether the caller should now call replay, which performs the actual
+// (potentially slow) redelivery outside of cb.mu.
+func (cb *circuitBreakerSink) report(err error) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case circuitHalfOpen:
+		cb.probing = false
+		if err == nil {
+			cb.toClosedLocked()
+			return true
+		}
+		cb.toOpenLocked()
+		return false
+	case circuitClosed:
+		cb.total++
+		if err != nil {
+			cb.failures++
+		}
+		if cb.total >= cb.policy.MinRequests && float64(cb.failures)/float64(cb.total) >= cb.policy.FailureThreshold {
+			cb.toOpenLocked()
+		}
+		return false
+	default: // circuitOpen: a call admitted just before the trip; the
+		// breaker is already open and counting it would only push back
+		// openedAt and restart the cooldown, so there is nothing to do.
+		return false
+	}
+}
+
+func (cb *circuitBreakerSink) toOpenLocked() {
+	if cb.state != circuitOpen && cb.listener != nil {
+		cb.listener.opened()
+	}
+	cb.state = circuitOpen
+	cb.openedAt = cb.clock.Now()
+	cb.total, cb.failures = 0, 0
+}
+
+func (cb *circuitBreakerSink) toClosedLocked() {
+	cb.state = circuitClosed
+	cb.total, cb.failures = 0, 0
+	if cb.listener != nil {
+		cb.listener.closed()
+	}
+}
+
+// replay redelivers up to policy.ReplayBudget dead-lettered events, in
+// FIFO order, through the now-closed underlying sink. It runs outside of
+// cb.mu so a slow or retrying delivery doesn't stall concurrent Write
+// calls, and releases dlqMu across the call to cb.sink.Write, reacquiring
+// it only to re-enqueue on failure, so a concurrent deadLetter still
+// fails fast instead of blocking on the whole redelivery. The trade-off:
+// a dead-letter append that lands while the redelivery is in flight will
+// sit ahead, in dlq, of events this replay fails to redeliver, so a
+// failed replay can redeliver those events out of original FIFO order.
+// Re-enqueued events are appended at the tail of dlq, so they also end up
+// behind any events this replay left in dlq beyond ReplayBudget.
+func (cb *circuitBreakerSink) replay() {
+	if cb.dlq == nil || cb.policy.ReplayBudget <= 0 {
+		return
+	}
+
+	cb.dlqMu.Lock()
+	events, err := cb.dlq.Read(cb.policy.ReplayBudget)
+	cb.dlqMu.Unlock()
+	if err != nil || len(events) == 0 {
+		return
+	}
+
+	if err := cb.sink.Write(events...); err != nil {
+		// The endpoint went back down mid-replay; re-enqueue rather than
+		// lose the events, and let the next successful probe try again.
+		cb.dlqMu.Lock()
+		cb.dlq.Write(events...)
+		cb.dlqMu.Unlock()
+		return
+	}
+
+	if cb.listener != nil {
+		cb.listener.replayed(len(events))
+	}
+}
+
+// deadLetter buffers events (when a DeadLetterSink is configured) and
+// returns the CircuitOpenError callers should see while the breaker is
+// open.
+func (cb *circuitBreakerSink) deadLetter(events []Event) error {
+	if cb.dlq != nil && len(events) > 0 {
+		cb.dlqMu.Lock()
+		err := cb.dlq.Write(events...)
+		cb.dlqMu.Unlock()
+
+		if err == nil && cb.listener != nil {
+			cb.listener.deadLettered(len(events))
+		}
+	}
+
+	return &CircuitOpenError{Endpoint: cb.endpoint}
+}
+
+// Close closes the underlying sink and dead letter sink.
+func (cb *circuitBreakerSink) Close() error {
+	err := cb.sink.Close()
+
+	if cb.dlq != nil {
+		if dlqErr := cb.dlq.Close(); dlqErr != nil && err == nil {
+			err = dlqErr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,165 @@
+package notifications
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable clock used to drive circuitBreakerSink's state
+// machine deterministically.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// fakeSink is a Sink whose success or failure is controlled by the test.
+type fakeSink struct {
+	fail   bool
+	events []Event
+}
+
+func (s *fakeSink) Write(events ...Event) error {
+	if s.fail {
+		return errors.New("fake sink failure")
+	}
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *fakeSink) Close() error { return nil }
+
+// TestCircuitBreakerSink drives a circuitBreakerSink's closed->open->
+// half-open->open->half-open->closed lifecycle with a fake clock,
+// checking that it fails fast and dead-letters events while open, and
+// replays the dead letter queue once a probe succeeds.
+func TestCircuitBreakerSink(t *testing.T) {
+	dlq, err := newFileDeadLetterSink(filepath.Join(t.TempDir(), "dlq.jsonl"))
+	if err != nil {
+		t.Fatalf("error creating dead letter sink: %v", err)
+	}
+
+	sink := &fakeSink{}
+	metrics := newSafeMetrics()
+	clk := &fakeClock{now: time.Unix(0, 0)}
+
+	cb := newCircuitBreakerSink("endpoint", sink, dlq, CircuitBreakerPolicy{
+		FailureThreshold: 0.5,
+		MinRequests:      2,
+		Cooldown:         10 * time.Second,
+		ReplayBudget:     10,
+	}, &endpointMetricsHTTPStatusListener{safeMetrics: metrics})
+	cb.clock = clk
+
+	// Two failures out of the minimum two requests trips the breaker.
+	sink.fail = true
+	for i := 0; i < 2; i++ {
+		if err := cb.Write(createTestEvent("push", "library/test", "manifest")); err == nil {
+			t.Fatalf("expected the underlying failure to propagate")
+		}
+	}
+	if metrics.CircuitOpens != 1 {
+		t.Fatalf("expected the breaker to have tripped open, got %#v", metrics.EndpointMetrics)
+	}
+
+	// While open, writes fail fast with CircuitOpenError and are
+	// dead-lettered instead of reaching the underlying sink.
+	if err := cb.Write(createTestEvent("push", "library/test", "manifest")); err == nil {
+		t.Fatalf("expected a CircuitOpenError while the breaker is open")
+	} else if _, ok := err.(*CircuitOpenError); !ok {
+		t.Fatalf("expected *CircuitOpenError, got %T: %v", err, err)
+	}
+	if depth, err := dlq.Depth(); err != nil || depth != 1 {
+		t.Fatalf("expected one dead-lettered event, got depth=%d err=%v", depth, err)
+	}
+	if metrics.DeadLetterDepth != 1 {
+		t.Fatalf("expected metrics to reflect the dead letter depth, got %#v", metrics.EndpointMetrics)
+	}
+
+	// Before the cooldown elapses, the breaker stays open.
+	clk.Advance(5 * time.Second)
+	if err := cb.Write(createTestEvent("push", "library/test", "manifest")); err == nil {
+		t.Fatalf("expected the breaker to remain open before the cooldown elapses")
+	}
+
+	// Once the cooldown elapses, a failing probe reopens the breaker and
+	// restarts the cooldown.
+	clk.Advance(6 * time.Second)
+	if err := cb.Write(createTestEvent("push", "library/test", "manifest")); err == nil {
+		t.Fatalf("expected the half-open probe to fail and reopen the breaker")
+	}
+	if metrics.CircuitOpens != 2 {
+		t.Fatalf("expected a second open transition after the failed probe, got %#v", metrics.EndpointMetrics)
+	}
+
+	// After the cooldown elapses again, a successful probe closes the
+	// breaker and replays the buffered dead letter events in FIFO order.
+	clk.Advance(11 * time.Second)
+	sink.fail = false
+	if err := cb.Write(createTestEvent("push", "library/test", "manifest")); err != nil {
+		t.Fatalf("expected the probe to succeed: %v", err)
+	}
+
+	if depth, err := dlq.Depth(); err != nil || depth != 0 {
+		t.Fatalf("expected the dead letter queue to be drained by replay, got depth=%d err=%v", depth, err)
+	}
+	if metrics.DeadLetterDepth != 0 {
+		t.Fatalf("expected metrics dead letter depth to return to zero, got %#v", metrics.EndpointMetrics)
+	}
+	if len(sink.events) != 3 {
+		t.Fatalf("expected the probe event plus two replayed events to reach the sink, got %d", len(sink.events))
+	}
+
+	if err := cb.Close(); err != nil {
+		t.Fatalf("unexpected error closing circuit breaker sink: %v", err)
+	}
+}
+
+// TestCircuitBreakerSinkReplayBudget ensures replay honors ReplayBudget,
+// leaving events beyond the budget buffered for a later probe.
+func TestCircuitBreakerSinkReplayBudget(t *testing.T) {
+	dlq, err := newFileDeadLetterSink(filepath.Join(t.TempDir(), "dlq.jsonl"))
+	if err != nil {
+		t.Fatalf("error creating dead letter sink: %v", err)
+	}
+
+	if err := dlq.Write(
+		createTestEvent("push", "library/test", "manifest"),
+		createTestEvent("push", "library/test", "layer"),
+		createTestEvent("push", "library/test", "layer"),
+	); err != nil {
+		t.Fatalf("unexpected error seeding dead letter queue: %v", err)
+	}
+
+	sink := &fakeSink{}
+	metrics := newSafeMetrics()
+	clk := &fakeClock{now: time.Unix(0, 0)}
+
+	cb := newCircuitBreakerSink("endpoint", sink, dlq, CircuitBreakerPolicy{
+		FailureThreshold: 0.5,
+		MinRequests:      1,
+		Cooldown:         time.Second,
+		ReplayBudget:     2,
+	}, &endpointMetricsHTTPStatusListener{safeMetrics: metrics})
+	cb.clock = clk
+
+	// Trip the breaker open directly via a single failure.
+	sink.fail = true
+	if err := cb.Write(createTestEvent("push", "library/test", "manifest")); err == nil {
+		t.Fatalf("expected the underlying failure to propagate")
+	}
+
+	clk.Advance(2 * time.Second)
+	sink.fail = false
+	if err := cb.Write(createTestEvent("push", "library/test", "manifest")); err != nil {
+		t.Fatalf("expected the probe to succeed: %v", err)
+	}
+
+	if depth, err := dlq.Depth(); err != nil || depth != 1 {
+		t.Fatalf("expected one event left buffered after a budget-limited replay, got depth=%d err=%v", depth, err)
+	}
+}
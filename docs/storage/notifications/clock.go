@@ -0,0 +1,17 @@
+package notifications
+
+import "time"
+
+// clock abstracts time retrieval so that components with time-driven
+// state, such as circuitBreakerSink, can be exercised deterministically
+// in tests.
+type clock interface {
+	Now() time.Time
+}
+
+// realClock is the clock used outside of tests.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
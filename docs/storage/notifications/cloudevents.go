@@ -0,0 +1,78 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// CloudEventsBatchMediaType is the content type used when delivering
+// events as a CloudEvents 1.0 batch, per the CloudEvents JSON batch
+// format (https://github.com/cloudevents/spec).
+const CloudEventsBatchMediaType = "application/cloudevents-batch+json"
+
+// cloudEvent is the JSON representation of a single CloudEvents 1.0
+// event, as produced by cloudEventsEncoder.
+type cloudEvent struct {
+	SpecVersion     string `json:"specversion"`
+	ID              string `json:"id"`
+	Source          string `json:"source"`
+	Type            string `json:"type"`
+	Subject         string `json:"subject,omitempty"`
+	Time            string `json:"time,omitempty"`
+	DataContentType string `json:"datacontenttype,omitempty"`
+	Data            Target `json:"data,omitempty"`
+}
+
+// cloudEventsEncoder maps registry Events onto a batched CloudEvents 1.0
+// JSON payload, letting the registry plug into existing eventing
+// infrastructure (Knative, Argo Events, EventBridge) without a
+// translation shim.
+type cloudEventsEncoder struct {
+	// source is used as the "source" attribute of every CloudEvent,
+	// typically the registry's own externally reachable URL.
+	source string
+}
+
+// newCloudEventsEncoder returns an Encoder that emits events as a
+// CloudEvents 1.0 batch, attributing each event to the given source.
+func newCloudEventsEncoder(source string) *cloudEventsEncoder {
+	return &cloudEventsEncoder{source: source}
+}
+
+func (e *cloudEventsEncoder) ContentType() string {
+	return CloudEventsBatchMediaType
+}
+
+func (e *cloudEventsEncoder) Encode(w io.Writer, events []Event) error {
+	batch := make([]cloudEvent, 0, len(events))
+	for _, event := range events {
+		batch = append(batch, cloudEvent{
+			SpecVersion:     "1.0",
+			ID:              event.ID,
+			Source:          e.source,
+			Type:            fmt.Sprintf("io.distribution.registry.%s.%s", event.Action, event.Target.Type),
+			Subject:         event.Target.Name,
+			Time:            event.Timestamp.Format(time.RFC3339Nano),
+			DataContentType: ociDataContentType(event.Target.Type),
+			Data:            event.Target,
+		})
+	}
+
+	return json.NewEncoder(w).Encode(batch)
+}
+
+// ociDataContentType reports the OCI media type describing the
+// notification payload for a given target kind, used to populate the
+// CloudEvents "datacontenttype" attribute.
+func ociDataContentType(targetType string) string {
+	switch targetType {
+	case "manifest":
+		return "application/vnd.oci.image.manifest.v1+json"
+	case "layer", "blob":
+		return "application/vnd.oci.image.layer.v1.tar+gzip"
+	default:
+		return "application/vnd.oci.descriptor.v1+json"
+	}
+}
@@ -0,0 +1,153 @@
+package notifications
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// DeadLetterSink buffers events that a circuitBreakerSink could not
+// deliver while its breaker was open, so they can be replayed once the
+// endpoint recovers. Implementations must preserve FIFO order between
+// Write and Read so replay does not reorder notifications.
+type DeadLetterSink interface {
+	// Write appends events to the tail of the queue.
+	Write(events ...Event) error
+
+	// Read removes and returns up to max events from the head of the
+	// queue, in the order they were written. A max <= 0 reads everything
+	// buffered.
+	Read(max int) ([]Event, error)
+
+	// Depth reports the number of events currently buffered.
+	Depth() (int, error)
+
+	// Close releases any resources held by the sink.
+	Close() error
+}
+
+// fileDeadLetterSink is the default DeadLetterSink, persisting buffered
+// events as newline-delimited JSON so they survive a registry restart.
+// Other backends (S3, Kafka, ...) can be added by implementing
+// DeadLetterSink.
+type fileDeadLetterSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// newFileDeadLetterSink returns a DeadLetterSink backed by the JSONL file
+// at path, creating it if it does not already exist.
+func newFileDeadLetterSink(path string) (*fileDeadLetterSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("notifications: error creating dead letter file: %v", err)
+	}
+	f.Close()
+
+	return &fileDeadLetterSink{path: path}, nil
+}
+
+func (f *fileDeadLetterSink) Write(events ...Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	enc := json.NewEncoder(file)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fileDeadLetterSink) Read(max int) ([]Event, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	all, err := f.readAllLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	if max <= 0 || max > len(all) {
+		max = len(all)
+	}
+
+	if err := f.rewriteLocked(all[max:]); err != nil {
+		return nil, err
+	}
+
+	return all[:max], nil
+}
+
+func (f *fileDeadLetterSink) Depth() (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	all, err := f.readAllLocked()
+	if err != nil {
+		return 0, err
+	}
+	return len(all), nil
+}
+
+func (f *fileDeadLetterSink) Close() error {
+	return nil
+}
+
+func (f *fileDeadLetterSink) readAllLocked() ([]Event, error) {
+	file, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, scanner.Err()
+}
+
+func (f *fileDeadLetterSink) rewriteLocked(remaining []Event) error {
+	tmp := f.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(file)
+	for _, event := range remaining {
+		if err := enc.Encode(event); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, f.path)
+}
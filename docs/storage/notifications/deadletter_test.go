@@ -0,0 +1,57 @@
+package notifications
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFileDeadLetterSink(t *testing.T) {
+	dlq, err := newFileDeadLetterSink(filepath.Join(t.TempDir(), "dlq.jsonl"))
+	if err != nil {
+		t.Fatalf("error creating dead letter sink: %v", err)
+	}
+	defer dlq.Close()
+
+	if depth, err := dlq.Depth(); err != nil || depth != 0 {
+		t.Fatalf("expected an empty queue, got depth=%d err=%v", depth, err)
+	}
+
+	first := createTestEvent("push", "library/test", "manifest")
+	second := createTestEvent("push", "library/test", "layer")
+	third := createTestEvent("delete", "library/test", "manifest")
+
+	if err := dlq.Write(first, second); err != nil {
+		t.Fatalf("unexpected error writing events: %v", err)
+	}
+	if err := dlq.Write(third); err != nil {
+		t.Fatalf("unexpected error writing events: %v", err)
+	}
+
+	if depth, err := dlq.Depth(); err != nil || depth != 3 {
+		t.Fatalf("expected depth 3, got depth=%d err=%v", depth, err)
+	}
+
+	got, err := dlq.Read(2)
+	if err != nil {
+		t.Fatalf("unexpected error reading events: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != first.ID || got[1].ID != second.ID {
+		t.Fatalf("expected the first two events in FIFO order, got %#v", got)
+	}
+
+	if depth, err := dlq.Depth(); err != nil || depth != 1 {
+		t.Fatalf("expected one event remaining, got depth=%d err=%v", depth, err)
+	}
+
+	rest, err := dlq.Read(10)
+	if err != nil {
+		t.Fatalf("unexpected error reading remaining events: %v", err)
+	}
+	if len(rest) != 1 || rest[0].ID != third.ID {
+		t.Fatalf("expected the remaining event to be the third, got %#v", rest)
+	}
+
+	if depth, err := dlq.Depth(); err != nil || depth != 0 {
+		t.Fatalf("expected the queue to be drained, got depth=%d err=%v", depth, err)
+	}
+}
@@ -0,0 +1,38 @@
+package notifications
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Encoder serializes a batch of events for delivery to a sink and reports
+// the Content-Type that should accompany the serialized payload. Sinks
+// that deliver over a byte-oriented transport, such as httpSink, use this
+// to decouple the wire format from the delivery mechanism.
+type Encoder interface {
+	// ContentType returns the MIME type to send alongside data produced
+	// by Encode.
+	ContentType() string
+
+	// Encode writes the serialized form of events to w.
+	Encode(w io.Writer, events []Event) error
+}
+
+// jsonEncoder is the default Encoder, preserving the original envelope
+// format consumed by existing registry notification receivers.
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string {
+	return EventsMediaType
+}
+
+func (jsonEncoder) Encode(w io.Writer, events []Event) error {
+	envelope := Envelope{
+		EventsVersion: EventVersion,
+		Events:        events,
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "   ")
+	return enc.Encode(envelope)
+}
@@ -0,0 +1,45 @@
+package notifications
+
+import "time"
+
+// EventsMediaType is the media type for the Envelope type used in the
+// notification system.
+const EventsMediaType = "application/vnd.docker.distribution.events.v1+json"
+
+// EventVersion is the version of the event format as it is reported in
+// Envelope. Any change to the event format should increment this number.
+const EventVersion = "2.0"
+
+// Event provides the fields required to describe a registry event.
+type Event struct {
+	// ID provides a unique identifier for the event.
+	ID string `json:"id,omitempty"`
+
+	// Timestamp is the time at which the event occurred.
+	Timestamp time.Time `json:"timestamp,omitempty"`
+
+	// Action indicates what action encompasses the provided event.
+	Action string `json:"action,omitempty"`
+
+	// Target describes the object this event pertains to.
+	Target Target `json:"target,omitempty"`
+}
+
+// Target describes the object that was the subject of an event.
+type Target struct {
+	// Type identifies the object kind, such as "manifest" or "layer".
+	Type string `json:"type,omitempty"`
+
+	// Name identifies the repository the object belongs to.
+	Name string `json:"name,omitempty"`
+}
+
+// Envelope provides the fields of the notification envelope, which wraps a
+// batch of events for delivery to a sink.
+type Envelope struct {
+	// EventsVersion is the version of the events in this envelope.
+	EventsVersion string `json:"events_version"`
+
+	// Events make up the contents of the envelope.
+	Events []Event `json:"events"`
+}
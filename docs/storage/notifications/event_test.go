@@ -0,0 +1,19 @@
+package notifications
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+var testEventSeq int64
+
+// createEvent returns a new Event with a unique id and the current time as
+// its timestamp, for use in sink tests.
+func createEvent(action string) *Event {
+	return &Event{
+		ID:        fmt.Sprintf("event-%d", atomic.AddInt64(&testEventSeq, 1)),
+		Timestamp: time.Now(),
+		Action:    action,
+	}
+}
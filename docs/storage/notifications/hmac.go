@@ -0,0 +1,111 @@
+package notifications
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// signatureHeader carries the timestamp and one or more HMAC-SHA256
+	// signatures of the request body, one per configured SigningSecret.
+	signatureHeader = "X-Registry-Signature"
+
+	// timestampHeader duplicates the signed timestamp outside of
+	// signatureHeader for receivers that want it without parsing.
+	timestampHeader = "X-Registry-Timestamp"
+)
+
+// Errors returned by VerifySignature.
+var (
+	ErrMissingSignature  = errors.New("notifications: missing or malformed signature header")
+	ErrSignatureExpired  = errors.New("notifications: signature timestamp outside allowed skew")
+	ErrSignatureMismatch = errors.New("notifications: signature does not match any configured secret")
+)
+
+// SigningSecret pairs a key id with the HMAC-SHA256 secret used to sign
+// outbound webhook bodies under that id. Configuring more than one
+// SigningSecret lets a secret be rotated without downtime: httpSink signs
+// every request with all configured secrets, so receivers can verify
+// against whichever one they have already rolled out.
+type SigningSecret struct {
+	KeyID  string
+	Secret []byte
+}
+
+// sign computes the signatureHeader value for body, signed at now with
+// every secret in secrets, along with the paired timestampHeader value.
+//
+// The value has the form "t=<unix>,v1=<hex>,kid=<id>[,v1=<hex>,kid=<id>...]",
+// with one v1/kid pair per secret so receivers can identify which key
+// produced a match.
+func sign(secrets []SigningSecret, now time.Time, body []byte) (signature, timestamp string) {
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	parts := make([]string, 0, 1+2*len(secrets))
+	parts = append(parts, "t="+ts)
+	for _, s := range secrets {
+		mac := hmac.New(sha256.New, s.Secret)
+		mac.Write([]byte(ts))
+		mac.Write([]byte("."))
+		mac.Write(body)
+		parts = append(parts, "v1="+hex.EncodeToString(mac.Sum(nil)), "kid="+s.KeyID)
+	}
+
+	return strings.Join(parts, ","), ts
+}
+
+// VerifySignature validates that header, the value of signatureHeader, is
+// a valid signature of body by at least one of secrets, signed within
+// skew of now. It is the counterpart to sign, intended for use by webhook
+// receivers (and by this package's own tests).
+func VerifySignature(secrets []SigningSecret, header string, body []byte, skew time.Duration, now time.Time) error {
+	var timestamp string
+	var signatures []string
+
+	for _, field := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			timestamp = kv[1]
+		case "v1":
+			signatures = append(signatures, kv[1])
+		}
+	}
+
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrMissingSignature
+	}
+
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrMissingSignature
+	}
+
+	if age := now.Sub(time.Unix(sec, 0)); age > skew || age < -skew {
+		return ErrSignatureExpired
+	}
+
+	for _, secret := range secrets {
+		mac := hmac.New(sha256.New, secret.Secret)
+		mac.Write([]byte(timestamp))
+		mac.Write([]byte("."))
+		mac.Write(body)
+		expected := hex.EncodeToString(mac.Sum(nil))
+
+		for _, sig := range signatures {
+			if hmac.Equal([]byte(sig), []byte(expected)) {
+				return nil
+			}
+		}
+	}
+
+	return ErrSignatureMismatch
+}
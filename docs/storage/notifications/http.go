@@ -0,0 +1,294 @@
+package notifications
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy configures how an httpSink retries a failed delivery attempt
+// before giving up on an event batch.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of delivery attempts for a single
+	// Write call. A value of 1 (or less) disables retries entirely.
+	MaxAttempts int
+
+	// BaseDelay is the backoff delay used for the first retry. Each
+	// subsequent retry doubles the previous delay, capped at MaxDelay.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the computed backoff delay, before any Retry-After
+	// floor from the endpoint is applied.
+	MaxDelay time.Duration
+
+	// Jitter, when true, selects the actual delay uniformly from
+	// [0, computed delay] ("full jitter"), so that retrying clients don't
+	// all hammer the endpoint in lockstep.
+	Jitter bool
+
+	// Retryable reports whether a failed attempt should be retried, given
+	// the response status code (0 for a transport-level error) and the
+	// transport error, if any. When nil, defaultRetryable is used.
+	Retryable func(statusCode int, err error) bool
+}
+
+// DefaultRetryPolicy is used by newHTTPSink when no RetryPolicy is given.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Jitter:      true,
+		Retryable:   defaultRetryable,
+	}
+}
+
+// defaultRetryable treats transport errors and the usual transient HTTP
+// statuses (request timeout, rate limiting, server errors) as retryable.
+// Other 4xx responses indicate the endpoint has rejected the request
+// outright, so retrying would just add load without changing the outcome.
+func defaultRetryable(statusCode int, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch statusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) retryable(statusCode int, err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(statusCode, err)
+	}
+	return defaultRetryable(statusCode, err)
+}
+
+// backoff computes the delay after the given failed attempt (1-indexed)
+// and before the next one, using
+// exponential backoff with full jitter, floored by retryAfter when the
+// endpoint supplied one.
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = 30 * time.Second
+	}
+
+	delay := float64(base) * math.Pow(2, float64(attempt-1))
+	if delay > float64(maxDelay) {
+		delay = float64(maxDelay)
+	}
+
+	d := time.Duration(delay)
+	if p.Jitter && d > 0 {
+		d = time.Duration(rand.Int63n(int64(d) + 1))
+	}
+
+	if retryAfter > d {
+		d = retryAfter
+	}
+
+	return d
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which may be
+// either a number of seconds or an HTTP-date, returning the remaining
+// delay. An unparsable or past value yields zero.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// newHTTPSink validates the destination, and returns a sink that can
+// post notifications to the endpoint. When retryPolicy is nil,
+// DefaultRetryPolicy is used. When encoder is nil, events are serialized
+// as the original JSON envelope. When secrets is non-empty, every request
+// is HMAC-signed under each of them; see SigningSecret.
+func newHTTPSink(u string, timeout time.Duration, headers http.Header, encoder Encoder, secrets []SigningSecret, statusListener httpStatusListener, retryPolicy *RetryPolicy) *httpSink {
+	policy := DefaultRetryPolicy()
+	if retryPolicy != nil {
+		policy = *retryPolicy
+	}
+
+	if encoder == nil {
+		encoder = jsonEncoder{}
+	}
+
+	return &httpSink{
+		url:     u,
+		timeout: timeout,
+		headers: headers,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+		listener: statusListener,
+		retry:    policy,
+		encoder:  encoder,
+		secrets:  secrets,
+	}
+}
+
+// httpSink implements a single-endpoint Sink over HTTP. Events are
+// delivered as a batch serialized by the sink's Encoder, retrying
+// transient failures according to the configured RetryPolicy.
+type httpSink struct {
+	url     string
+	timeout time.Duration
+	headers http.Header
+	client  *http.Client
+	retry   RetryPolicy
+	encoder Encoder
+	secrets []SigningSecret
+
+	mu       sync.Mutex
+	closed   bool
+	listener httpStatusListener
+}
+
+// Write accepts one or more events and sends them as a single batch to
+// the configured endpoint, retrying according to the sink's RetryPolicy.
+func (hs *httpSink) Write(events ...Event) error {
+	hs.mu.Lock()
+	closed := hs.closed
+	hs.mu.Unlock()
+	if closed {
+		return ErrSinkClosed
+	}
+
+	var buf bytes.Buffer
+	if err := hs.encoder.Encode(&buf, events); err != nil {
+		return fmt.Errorf("%v: error encoding events: %v", hs, err)
+	}
+	body := buf.Bytes()
+
+	maxAttempts := hs.retry.maxAttempts()
+	var lastErr error
+	var lastStatus int
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		statusCode, retryAfter, err := hs.post(body)
+		if err == nil && statusCode >= 200 && statusCode < 400 {
+			hs.listener.success(statusCode, events...)
+			return nil
+		}
+
+		lastErr, lastStatus = err, statusCode
+
+		if !hs.retry.retryable(statusCode, err) {
+			hs.listener.failure(statusCode, events...)
+			return hs.deliveryError(lastStatus, lastErr)
+		}
+
+		if attempt == maxAttempts {
+			if maxAttempts > 1 {
+				hs.listener.retriesExhausted(statusCode, events...)
+			}
+			hs.listener.failure(statusCode, events...)
+			return hs.deliveryError(lastStatus, lastErr)
+		}
+
+		hs.listener.retry(statusCode, events...)
+		time.Sleep(hs.retry.backoff(attempt, retryAfter))
+	}
+
+	// unreachable: the loop always returns on its last iteration.
+	return hs.deliveryError(lastStatus, lastErr)
+}
+
+func (hs *httpSink) deliveryError(statusCode int, err error) error {
+	if err != nil {
+		return fmt.Errorf("%v: error posting: %v", hs, err)
+	}
+	return fmt.Errorf("%v: response status %v unaccepted", hs, statusCode)
+}
+
+// post sends a single delivery attempt, returning the response status
+// code, any Retry-After delay it specified, and a transport-level error.
+func (hs *httpSink) post(body []byte) (statusCode int, retryAfter time.Duration, err error) {
+	req, err := http.NewRequest(http.MethodPost, hs.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", hs.encoder.ContentType())
+
+	if len(hs.secrets) > 0 {
+		signature, timestamp := sign(hs.secrets, time.Now(), body)
+		req.Header.Set(signatureHeader, signature)
+		req.Header.Set(timestampHeader, timestamp)
+	}
+
+	for k, vs := range hs.headers {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+
+	resp, err := hs.client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the underlying connection can be reused by the
+	// transport's connection pool instead of forcing a new connection on
+	// every retry or subsequent delivery to this endpoint.
+	io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, parseRetryAfter(resp.Header.Get("Retry-After")), nil
+}
+
+// Close closes the sink, providing no further retries or event emission
+// from the caller. Close returns an error if it has already been closed.
+func (hs *httpSink) Close() error {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	if hs.closed {
+		return fmt.Errorf("httpSink: already closed")
+	}
+
+	hs.closed = true
+	return nil
+}
+
+func (hs *httpSink) String() string {
+	return fmt.Sprintf("httpSink{url: %v}", hs.url)
+}
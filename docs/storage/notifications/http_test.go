@@ -3,12 +3,15 @@ package notifications
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"mime"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // TestHTTPSink mocks out an http endpoint and notifies it under a couple of
@@ -58,8 +61,12 @@ func TestHTTPSink(t *testing.T) {
 	}))
 
 	metrics := newSafeMetrics()
-	sink := newHTTPSink(server.URL, 0, nil,
-		&endpointMetricsHTTPStatusListener{safeMetrics: metrics})
+	// Disable retries here so the failure cases below (including the
+	// unresolvable host) fail deterministically on the first attempt;
+	// retry/backoff behavior is covered by TestHTTPSinkRetry.
+	sink := newHTTPSink(server.URL, 0, nil, nil, nil,
+		&endpointMetricsHTTPStatusListener{safeMetrics: metrics},
+		&RetryPolicy{MaxAttempts: 1})
 
 	var expectedMetrics EndpointMetrics
 	expectedMetrics.Statuses = make(map[string]int)
@@ -145,6 +152,282 @@ func TestHTTPSink(t *testing.T) {
 
 }
 
+// TestHTTPSinkRetry ensures that httpSink retries a 503 response that
+// carries a Retry-After header, eventually succeeding once the endpoint
+// recovers, and that the retry counter reflects the attempts made.
+func TestHTTPSinkRetry(t *testing.T) {
+	const failuresBeforeSuccess = 2
+
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		if atomic.AddInt64(&calls, 1) <= failuresBeforeSuccess {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	metrics := newSafeMetrics()
+	sink := newHTTPSink(server.URL, 0, nil, nil, nil,
+		&endpointMetricsHTTPStatusListener{safeMetrics: metrics},
+		&RetryPolicy{
+			MaxAttempts: failuresBeforeSuccess + 1,
+			BaseDelay:   time.Millisecond,
+			MaxDelay:    10 * time.Millisecond,
+			Jitter:      true,
+		})
+
+	event := createTestEvent("push", "library/test", "manifest")
+	if err := sink.Write(event); err != nil {
+		t.Fatalf("unexpected error sending event after retries: %v", err)
+	}
+
+	if got := atomic.LoadInt64(&calls); got != failuresBeforeSuccess+1 {
+		t.Fatalf("expected %d calls, got %d", failuresBeforeSuccess+1, got)
+	}
+
+	if metrics.Retries != failuresBeforeSuccess {
+		t.Fatalf("expected %d retries recorded, got %d", failuresBeforeSuccess, metrics.Retries)
+	}
+
+	if metrics.Successes != 1 {
+		t.Fatalf("expected the eventual success to be recorded, got %#v", metrics.EndpointMetrics)
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Fatalf("unexpected error closing http sink: %v", err)
+	}
+}
+
+// TestHTTPSinkRetriesExhausted ensures that a persistently failing
+// retryable endpoint is retried up to MaxAttempts and then reported as a
+// failure with RetriesExhausted incremented.
+func TestHTTPSinkRetriesExhausted(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		atomic.AddInt64(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	const maxAttempts = 3
+
+	metrics := newSafeMetrics()
+	sink := newHTTPSink(server.URL, 0, nil, nil, nil,
+		&endpointMetricsHTTPStatusListener{safeMetrics: metrics},
+		&RetryPolicy{
+			MaxAttempts: maxAttempts,
+			BaseDelay:   time.Millisecond,
+			MaxDelay:    5 * time.Millisecond,
+		})
+
+	event := createTestEvent("push", "library/test", "manifest")
+	if err := sink.Write(event); err == nil {
+		t.Fatalf("expected the sink to report a failure after exhausting retries")
+	}
+
+	if got := atomic.LoadInt64(&calls); got != maxAttempts {
+		t.Fatalf("expected %d attempts, got %d", maxAttempts, got)
+	}
+
+	if metrics.Retries != maxAttempts-1 {
+		t.Fatalf("expected %d retries, got %d", maxAttempts-1, metrics.Retries)
+	}
+
+	if metrics.RetriesExhausted != 1 {
+		t.Fatalf("expected retries exhausted to be recorded, got %#v", metrics.EndpointMetrics)
+	}
+
+	if metrics.Failures != 1 {
+		t.Fatalf("expected the final failure to be recorded, got %#v", metrics.EndpointMetrics)
+	}
+}
+
+// TestHTTPSinkEncoders parametrizes TestHTTPSink-style delivery over each
+// supported Encoder, verifying that the negotiated Content-Type matches
+// the encoder and that the delivered payload round-trips back to the
+// original events.
+func TestHTTPSinkEncoders(t *testing.T) {
+	events := []Event{
+		createTestEvent("push", "library/test", "manifest"),
+		createTestEvent("push", "library/test", "layer"),
+	}
+
+	for _, tc := range []struct {
+		name    string
+		encoder Encoder
+		decode  func(t *testing.T, contentType string, body []byte) []Event
+	}{
+		{
+			name:    "JSON",
+			encoder: jsonEncoder{},
+			decode: func(t *testing.T, contentType string, body []byte) []Event {
+				var envelope Envelope
+				if err := json.Unmarshal(body, &envelope); err != nil {
+					t.Fatalf("error decoding JSON envelope: %v", err)
+				}
+				return envelope.Events
+			},
+		},
+		{
+			name:    "CloudEvents",
+			encoder: newCloudEventsEncoder("https://registry.example.com"),
+			decode: func(t *testing.T, contentType string, body []byte) []Event {
+				var batch []cloudEvent
+				if err := json.Unmarshal(body, &batch); err != nil {
+					t.Fatalf("error decoding CloudEvents batch: %v", err)
+				}
+
+				decoded := make([]Event, 0, len(batch))
+				for _, ce := range batch {
+					if ce.Source != "https://registry.example.com" {
+						t.Fatalf("unexpected CloudEvents source: %v", ce.Source)
+					}
+					decoded = append(decoded, Event{
+						ID:     ce.ID,
+						Action: ce.Type,
+						Target: ce.Data,
+					})
+				}
+				return decoded
+			},
+		},
+		{
+			name:    "Protobuf",
+			encoder: protobufEncoder{},
+			decode: func(t *testing.T, contentType string, body []byte) []Event {
+				decoded, err := decodeProtobufEvents(body)
+				if err != nil {
+					t.Fatalf("error decoding protobuf events: %v", err)
+				}
+				return decoded
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotContentType string
+			var gotBody []byte
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer r.Body.Close()
+				gotContentType = r.Header.Get("Content-Type")
+				gotBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			metrics := newSafeMetrics()
+			sink := newHTTPSink(server.URL, 0, nil, tc.encoder, nil,
+				&endpointMetricsHTTPStatusListener{safeMetrics: metrics},
+				&RetryPolicy{MaxAttempts: 1})
+
+			if err := sink.Write(events...); err != nil {
+				t.Fatalf("unexpected error sending events: %v", err)
+			}
+
+			mediaType, _, err := mime.ParseMediaType(gotContentType)
+			if err != nil {
+				t.Fatalf("error parsing media type: %v, contenttype=%q", err, gotContentType)
+			}
+			if mediaType != tc.encoder.ContentType() {
+				t.Fatalf("incorrect media type: %q != %q", mediaType, tc.encoder.ContentType())
+			}
+
+			decoded := tc.decode(t, gotContentType, gotBody)
+			if len(decoded) != len(events) {
+				t.Fatalf("round trip lost events: got %d, want %d", len(decoded), len(events))
+			}
+			for i, event := range events {
+				if decoded[i].ID != event.ID || decoded[i].Target.Type != event.Target.Type || decoded[i].Target.Name != event.Target.Name {
+					t.Fatalf("round-tripped event %d did not match: %#v != %#v", i, decoded[i], event)
+				}
+			}
+		})
+	}
+}
+
+// TestHTTPSinkSigning covers both a single signing secret and a rotating
+// pair of secrets, verifying that a receiver recomputing the HMAC over
+// the received body accepts the signature, rejects a tampered or
+// unrecognized one, and rejects a signature whose timestamp falls
+// outside the allowed skew window.
+func TestHTTPSinkSigning(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		secrets []SigningSecret
+	}{
+		{
+			name: "single secret",
+			secrets: []SigningSecret{
+				{KeyID: "key1", Secret: []byte("s3cr3t")},
+			},
+		},
+		{
+			name: "rotating secrets",
+			secrets: []SigningSecret{
+				{KeyID: "key1", Secret: []byte("old-secret")},
+				{KeyID: "key2", Secret: []byte("new-secret")},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var rejected int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer r.Body.Close()
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatalf("error reading body: %v", err)
+				}
+
+				if err := VerifySignature(tc.secrets, r.Header.Get("X-Registry-Signature"), body, 5*time.Minute, time.Now()); err != nil {
+					atomic.AddInt32(&rejected, 1)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				if r.Header.Get("X-Registry-Timestamp") == "" {
+					t.Fatalf("missing X-Registry-Timestamp header")
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			metrics := newSafeMetrics()
+			sink := newHTTPSink(server.URL, 0, nil, nil, tc.secrets,
+				&endpointMetricsHTTPStatusListener{safeMetrics: metrics},
+				&RetryPolicy{MaxAttempts: 1})
+
+			event := createTestEvent("push", "library/test", "manifest")
+			if err := sink.Write(event); err != nil {
+				t.Fatalf("unexpected error sending signed event: %v", err)
+			}
+			if atomic.LoadInt32(&rejected) != 0 {
+				t.Fatalf("receiver rejected a correctly signed request")
+			}
+
+			// A signature computed with an unrelated secret must be rejected.
+			bogus := fmt.Sprintf("t=%d,v1=deadbeef,kid=bogus", time.Now().Unix())
+			if err := VerifySignature(tc.secrets, bogus, []byte("tampered"), 5*time.Minute, time.Now()); err == nil {
+				t.Fatalf("expected a mismatched signature to be rejected")
+			}
+
+			// A validly-signed but stale timestamp must be rejected as a
+			// replay, even with the correct body and secret.
+			signature, _ := sign(tc.secrets, time.Now().Add(-time.Hour), []byte(`{}`))
+			if err := VerifySignature(tc.secrets, signature, []byte(`{}`), 5*time.Minute, time.Now()); err != ErrSignatureExpired {
+				t.Fatalf("expected ErrSignatureExpired for a stale timestamp, got %v", err)
+			}
+		})
+	}
+}
+
 func createTestEvent(action, repo, typ string) Event {
 	event := createEvent(action)
 
@@ -0,0 +1,116 @@
+package notifications
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// EndpointMetrics track basic statistics about endpoint activity.
+type EndpointMetrics struct {
+	Pending          int            // events pending in queue
+	Events           int            // total events incurred
+	Successes        int            // total successful events
+	Failures         int            // total failed events
+	Retries          int            // total retry attempts across all events
+	RetriesExhausted int            // total events that exhausted their retry budget
+	CircuitOpens     int            // total number of times the circuit breaker tripped open
+	DeadLetterDepth  int            // events currently buffered in the dead letter queue
+	Statuses         map[string]int // status code histogram, counted per event
+}
+
+// safeMetrics guards EndpointMetrics with a mutex so it may be read and
+// updated from multiple goroutines.
+type safeMetrics struct {
+	EndpointMetrics
+	sync.Mutex
+}
+
+// newSafeMetrics returns a safeMetrics with the Statuses map pre-allocated.
+func newSafeMetrics() *safeMetrics {
+	var sm safeMetrics
+	sm.Statuses = make(map[string]int)
+	return &sm
+}
+
+// httpStatusListener is called by httpSink as it delivers events, allowing
+// observers to track the outcome of each delivery attempt.
+type httpStatusListener interface {
+	success(statusCode int, events ...Event)
+	failure(statusCode int, events ...Event)
+
+	// retry is called each time a delivery attempt is scheduled to be
+	// retried after a retryable failure.
+	retry(statusCode int, events ...Event)
+
+	// retriesExhausted is called when an event has failed on every
+	// attempt permitted by the sink's RetryPolicy.
+	retriesExhausted(statusCode int, events ...Event)
+}
+
+// endpointMetricsHTTPStatusListener maintains an EndpointMetrics struct,
+// updating it in response to httpSink delivery outcomes.
+type endpointMetricsHTTPStatusListener struct {
+	safeMetrics *safeMetrics
+}
+
+var _ httpStatusListener = &endpointMetricsHTTPStatusListener{}
+
+func (e *endpointMetricsHTTPStatusListener) success(statusCode int, events ...Event) {
+	e.safeMetrics.Lock()
+	defer e.safeMetrics.Unlock()
+
+	e.safeMetrics.Successes += len(events)
+	if statusCode > 0 {
+		e.safeMetrics.Statuses[fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode))] += len(events)
+	}
+}
+
+func (e *endpointMetricsHTTPStatusListener) failure(statusCode int, events ...Event) {
+	e.safeMetrics.Lock()
+	defer e.safeMetrics.Unlock()
+
+	e.safeMetrics.Failures += len(events)
+	if statusCode > 0 {
+		e.safeMetrics.Statuses[fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode))] += len(events)
+	}
+}
+
+func (e *endpointMetricsHTTPStatusListener) retry(statusCode int, events ...Event) {
+	e.safeMetrics.Lock()
+	defer e.safeMetrics.Unlock()
+
+	e.safeMetrics.Retries += len(events)
+}
+
+func (e *endpointMetricsHTTPStatusListener) retriesExhausted(statusCode int, events ...Event) {
+	e.safeMetrics.Lock()
+	defer e.safeMetrics.Unlock()
+
+	e.safeMetrics.RetriesExhausted += len(events)
+}
+
+var _ circuitBreakerListener = &endpointMetricsHTTPStatusListener{}
+
+func (e *endpointMetricsHTTPStatusListener) opened() {
+	e.safeMetrics.Lock()
+	defer e.safeMetrics.Unlock()
+
+	e.safeMetrics.CircuitOpens++
+}
+
+func (e *endpointMetricsHTTPStatusListener) closed() {}
+
+func (e *endpointMetricsHTTPStatusListener) deadLettered(n int) {
+	e.safeMetrics.Lock()
+	defer e.safeMetrics.Unlock()
+
+	e.safeMetrics.DeadLetterDepth += n
+}
+
+func (e *endpointMetricsHTTPStatusListener) replayed(n int) {
+	e.safeMetrics.Lock()
+	defer e.safeMetrics.Unlock()
+
+	e.safeMetrics.DeadLetterDepth -= n
+}
@@ -0,0 +1,156 @@
+package notifications
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ProtobufMediaType is the content type used when delivering events
+// encoded by protobufEncoder.
+const ProtobufMediaType = "application/x-protobuf"
+
+// protobufEncoder serializes events as a sequence of length-delimited,
+// field-compatible protobuf messages (one per event), using the standard
+// varint wire format directly rather than pulling in a full protobuf
+// runtime for a handful of scalar fields.
+type protobufEncoder struct{}
+
+func (protobufEncoder) ContentType() string {
+	return ProtobufMediaType
+}
+
+func (protobufEncoder) Encode(w io.Writer, events []Event) error {
+	var buf []byte
+	for _, event := range events {
+		msg := encodeEventProto(event)
+		buf = appendProtoTag(buf, 1, protoWireBytes)
+		buf = appendProtoVarint(buf, uint64(len(msg)))
+		buf = append(buf, msg...)
+	}
+
+	_, err := w.Write(buf)
+	return err
+}
+
+const (
+	protoWireVarint = 0
+	protoWireBytes  = 2
+)
+
+// encodeEventProto encodes a single Event as a protobuf message with the
+// following field layout:
+//
+//	1: id (string)
+//	2: action (string)
+//	3: timestamp (string, RFC3339Nano)
+//	4: target.type (string)
+//	5: target.name (string)
+func encodeEventProto(event Event) []byte {
+	var msg []byte
+	msg = appendProtoString(msg, 1, event.ID)
+	msg = appendProtoString(msg, 2, event.Action)
+	msg = appendProtoString(msg, 3, event.Timestamp.Format(time.RFC3339Nano))
+	msg = appendProtoString(msg, 4, event.Target.Type)
+	msg = appendProtoString(msg, 5, event.Target.Name)
+	return msg
+}
+
+func appendProtoTag(buf []byte, field int, wireType int) []byte {
+	return appendProtoVarint(buf, uint64(field)<<3|uint64(wireType))
+}
+
+func appendProtoVarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
+
+func appendProtoString(buf []byte, field int, s string) []byte {
+	if s == "" {
+		return buf
+	}
+	buf = appendProtoTag(buf, field, protoWireBytes)
+	buf = appendProtoVarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// decodeProtobufEvents decodes a payload produced by protobufEncoder back
+// into Events. It exists primarily to let receivers, and this package's
+// own tests, verify the wire format round-trips.
+func decodeProtobufEvents(buf []byte) ([]Event, error) {
+	var events []Event
+	for len(buf) > 0 {
+		tag, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, fmt.Errorf("notifications: invalid protobuf tag")
+		}
+		buf = buf[n:]
+
+		field, wireType := int(tag>>3), int(tag&0x7)
+		if field != 1 || wireType != protoWireBytes {
+			return nil, fmt.Errorf("notifications: unexpected protobuf field %d wire type %d", field, wireType)
+		}
+
+		length, n := binary.Uvarint(buf)
+		if n <= 0 || uint64(len(buf)-n) < length {
+			return nil, fmt.Errorf("notifications: invalid protobuf length")
+		}
+		buf = buf[n:]
+
+		event, err := decodeEventProto(buf[:length])
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+		buf = buf[length:]
+	}
+
+	return events, nil
+}
+
+func decodeEventProto(buf []byte) (Event, error) {
+	var event Event
+	for len(buf) > 0 {
+		tag, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return Event{}, fmt.Errorf("notifications: invalid protobuf tag")
+		}
+		buf = buf[n:]
+
+		field, wireType := int(tag>>3), int(tag&0x7)
+		if wireType != protoWireBytes {
+			return Event{}, fmt.Errorf("notifications: unexpected wire type %d for field %d", wireType, field)
+		}
+
+		length, n := binary.Uvarint(buf)
+		if n <= 0 || uint64(len(buf)-n) < length {
+			return Event{}, fmt.Errorf("notifications: invalid protobuf length")
+		}
+		buf = buf[n:]
+
+		value := string(buf[:length])
+		switch field {
+		case 1:
+			event.ID = value
+		case 2:
+			event.Action = value
+		case 3:
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return Event{}, fmt.Errorf("notifications: invalid protobuf timestamp: %v", err)
+			}
+			event.Timestamp = t
+		case 4:
+			event.Target.Type = value
+		case 5:
+			event.Target.Name = value
+		default:
+			return Event{}, fmt.Errorf("notifications: unknown protobuf field %d", field)
+		}
+		buf = buf[length:]
+	}
+
+	return event, nil
+}
@@ -0,0 +1,22 @@
+package notifications
+
+import "errors"
+
+// ErrSinkClosed is returned if a write is issued to a sink that has been
+// closed. If encountered, the error should be considered terminal and
+// retries will not be successful.
+var ErrSinkClosed = errors.New("notifications: sink closed")
+
+// Sink accepts and sends events.
+type Sink interface {
+	// Write writes one or more events to the sink. If no error is returned,
+	// the caller will assume that all events have been committed and will
+	// not try to send them again. If an error is received, the caller may
+	// retry sending the event. The caller should cede the slice of memory
+	// to the Sink when calling this method.
+	Write(events ...Event) error
+
+	// Close the sink. After this is called, further calls to Write will
+	// return ErrSinkClosed.
+	Close() error
+}